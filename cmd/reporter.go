@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/alphasoc/flightsim/version"
+	"github.com/fatih/color"
+)
+
+// Event is a single structured record describing one simulated action. It's
+// the unit a Reporter emits for SIEM ingestion.
+type Event struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Module     string    `json:"module"`
+	ExternalIP string    `json:"external_ip"`
+	TargetHost string    `json:"target_host"`
+	TargetPort string    `json:"target_port,omitempty"`
+	Action     string    `json:"action"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Reporter renders simulator progress and per-host events to the user.
+// TextReporter keeps the freeform output flightsim has always produced;
+// JSONReporter emits one Event per line for piping into a SIEM forwarder.
+type Reporter interface {
+	Welcome(ip string)
+	Header()
+	Msg(module string, msg ...string)
+	Event(e Event)
+	Goodbye()
+}
+
+// NewReporter returns the Reporter for the given --output mode ("text",
+// "json" or "ndjson"), writing to w.
+func NewReporter(output string, w io.Writer) (Reporter, error) {
+	switch output {
+	case "", "text":
+		return &TextReporter{w: w}, nil
+	case "json", "ndjson":
+		return &JSONReporter{w: w}, nil
+	default:
+		return nil, fmt.Errorf("output %q not recognized", output)
+	}
+}
+
+// TextReporter renders flightsim's original human readable output.
+type TextReporter struct {
+	w io.Writer
+}
+
+func (r *TextReporter) Welcome(ip string) {
+	fmt.Fprintf(r.w, `
+AlphaSOC Network Flight Simulator™ %s (https://github.com/alphasoc/flightsim)
+The IP address of the network interface is %s
+The current time is %s
+
+`, version.Version, ip, time.Now().Format("02-Jan-06 15:04:05"))
+}
+
+func (r *TextReporter) Header() {
+	fmt.Fprintln(r.w, "Time      Module   Description")
+	fmt.Fprintln(r.w, "--------------------------------------------------------------------------------")
+}
+
+func (r *TextReporter) Msg(module string, msg ...string) {
+	for i := range msg {
+		fmt.Fprintf(r.w, "%s  %-7s  %s\n", time.Now().Format("15:04:05"), module, msg[i])
+	}
+}
+
+func (r *TextReporter) Event(e Event) {
+	if e.Status == "error" {
+		r.Msg(e.Module, color.RedString("failed ")+e.Error)
+		return
+	}
+	if e.Action != "" {
+		r.Msg(e.Module, e.Action)
+	}
+}
+
+func (r *TextReporter) Goodbye() {
+	fmt.Fprintf(r.w, "\nAll done! Check your SIEM for alerts using the timestamps and details above.\n")
+}
+
+// JSONReporter emits one JSON record per simulated event (NDJSON) and stays
+// silent otherwise, so stdout can be piped straight into a SIEM forwarder.
+type JSONReporter struct {
+	w io.Writer
+}
+
+func (r *JSONReporter) Welcome(ip string)                {}
+func (r *JSONReporter) Header()                          {}
+func (r *JSONReporter) Msg(module string, msg ...string) {}
+
+func (r *JSONReporter) Event(e Event) {
+	// Encode errors are not actionable here: they mean the output stream
+	// itself is broken, and there's nothing left to report it through.
+	_ = json.NewEncoder(r.w).Encode(e)
+}
+
+func (r *JSONReporter) Goodbye() {}
+
+// syncReporter serializes calls onto an underlying Reporter so that
+// multiple modules running concurrently don't interleave their output.
+type syncReporter struct {
+	mu sync.Mutex
+	r  Reporter
+}
+
+func (s *syncReporter) Welcome(ip string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.r.Welcome(ip)
+}
+
+func (s *syncReporter) Header() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.r.Header()
+}
+
+func (s *syncReporter) Msg(module string, msg ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.r.Msg(module, msg...)
+}
+
+func (s *syncReporter) Event(e Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.r.Event(e)
+}
+
+func (s *syncReporter) Goodbye() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.r.Goodbye()
+}
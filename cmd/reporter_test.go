@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewReporterSelectsImplementation(t *testing.T) {
+	var buf bytes.Buffer
+
+	if r, err := NewReporter("", &buf); err != nil {
+		t.Fatalf("NewReporter(\"\") = %v", err)
+	} else if _, ok := r.(*TextReporter); !ok {
+		t.Errorf("NewReporter(\"\") = %T, want *TextReporter", r)
+	}
+
+	for _, output := range []string{"json", "ndjson"} {
+		if r, err := NewReporter(output, &buf); err != nil {
+			t.Fatalf("NewReporter(%q) = %v", output, err)
+		} else if _, ok := r.(*JSONReporter); !ok {
+			t.Errorf("NewReporter(%q) = %T, want *JSONReporter", output, r)
+		}
+	}
+
+	if _, err := NewReporter("xml", &buf); err == nil {
+		t.Fatal("NewReporter(\"xml\") should have been rejected")
+	}
+}
+
+func TestJSONReporterEventShape(t *testing.T) {
+	var buf bytes.Buffer
+	r := &JSONReporter{w: &buf}
+
+	r.Event(Event{
+		Timestamp:  time.Date(2026, 7, 26, 12, 0, 0, 0, time.UTC),
+		Module:     "scan",
+		ExternalIP: "203.0.113.1",
+		TargetHost: "203.0.113.2",
+		TargetPort: "22",
+		Action:     "Port scanning 203.0.113.2",
+		Status:     "ok",
+	})
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("decoding emitted line: %v", err)
+	}
+	for _, field := range []string{"timestamp", "module", "external_ip", "target_host", "target_port", "action", "status"} {
+		if _, ok := got[field]; !ok {
+			t.Errorf("emitted event missing %q field: %v", field, got)
+		}
+	}
+	if got["module"] != "scan" {
+		t.Errorf("module = %v, want scan", got["module"])
+	}
+}
+
+func TestJSONReporterOmitsEmptyErrorAndPort(t *testing.T) {
+	var buf bytes.Buffer
+	r := &JSONReporter{w: &buf}
+
+	r.Event(Event{Module: "dga", Status: "ok"})
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("decoding emitted line: %v", err)
+	}
+	if _, ok := got["error"]; ok {
+		t.Errorf("expected no error field when Error is empty, got %v", got)
+	}
+	if _, ok := got["target_port"]; ok {
+		t.Errorf("expected no target_port field when TargetPort is empty, got %v", got)
+	}
+}
+
+func TestTextReporterEventRendersFailures(t *testing.T) {
+	var buf bytes.Buffer
+	r := &TextReporter{w: &buf}
+
+	r.Event(Event{Module: "scan", Status: "error", Error: "connection refused"})
+
+	if !strings.Contains(buf.String(), "connection refused") {
+		t.Errorf("expected rendered error message, got %q", buf.String())
+	}
+}
+
+func TestTextReporterEventSkipsSilentHosts(t *testing.T) {
+	var buf bytes.Buffer
+	r := &TextReporter{w: &buf}
+
+	r.Event(Event{Module: "scan", Status: "ok"})
+
+	if buf.Len() != 0 {
+		t.Errorf("expected no output for an ok event without an Action, got %q", buf.String())
+	}
+}
+
+func TestSyncReporterForwardsToUnderlying(t *testing.T) {
+	var buf bytes.Buffer
+	sr := &syncReporter{r: &TextReporter{w: &buf}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sr.Msg("scan", "hello")
+		}(i)
+	}
+	wg.Wait()
+
+	if n := strings.Count(buf.String(), "hello"); n != 10 {
+		t.Errorf("got %d lines, want 10", n)
+	}
+}
@@ -3,42 +3,104 @@ package cmd
 import (
 	"fmt"
 	"net"
+	"net/url"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/alphasoc/flightsim/config"
 	"github.com/alphasoc/flightsim/simulator"
 	"github.com/alphasoc/flightsim/utils"
-	"github.com/alphasoc/flightsim/version"
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
 func newRunCommand() *cobra.Command {
 	var (
 		fast           bool
+		parallel       int
 		ifaceName      string
-		simulatorNames = []string{"c2-dns", "dga", "scan", "tunnel"}
+		output         string
+		outputFile     string
+		configPath     string
+		profileName    string
+		simulatorNames = registeredSimulatorNames()
 	)
 	cmd := &cobra.Command{
 		Use:   fmt.Sprintf("run [%s]", strings.Join(simulatorNames, "|")),
 		Short: "Run all simulators (default) or a particular test",
+		Long: `Run all simulators (default) or a particular test.
+
+Every selected module runs in its own goroutine concurrently, and each
+module simulates up to --parallel hosts at a time, so the number of hosts
+being actively simulated at once is (number of modules) * --parallel, not
+a single pool bounded to --parallel overall. Keep that multiplicative
+effect in mind when raising --parallel for a profile that runs several
+network-heavy modules (scan, miner, tor) together, since it multiplies
+the concurrent connections made to real hosts.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			for _, arg := range args {
-				if !utils.StringsContains(simulatorNames, arg) {
-					return fmt.Errorf("simulator %s not recognized", arg)
+			cfg, err := config.Load(configPath)
+			if err != nil {
+				return err
+			}
+
+			var profile config.Profile
+			if profileName != "" {
+				profile, err = cfg.Profile(profileName)
+				if err != nil {
+					return err
+				}
+				if len(args) == 0 && len(profile.Simulators) > 0 {
+					simulatorNames = profile.Simulators
+				}
+				if !cmd.Flags().Changed("interface") && profile.Interface != "" {
+					ifaceName = profile.Interface
+				}
+				if !cmd.Flags().Changed("parallel") && profile.Parallel > 0 {
+					parallel = profile.Parallel
+				}
+				if !cmd.Flags().Changed("output") && profile.Output != "" {
+					output = profile.Output
+				}
+				if !cmd.Flags().Changed("output-file") && profile.OutputFile != "" {
+					outputFile = profile.OutputFile
 				}
 			}
 
 			if len(args) > 0 {
 				simulatorNames = args
 			}
+			for _, name := range simulatorNames {
+				if !utils.StringsContains(registeredSimulatorNames(), name) {
+					return fmt.Errorf("simulator %s not recognized", name)
+				}
+			}
 
 			extIP, err := utils.ExternalIP(ifaceName)
 			if err != nil {
 				return err
 			}
 
+			w := cmd.OutOrStdout()
+			if outputFile != "" {
+				f, err := os.Create(outputFile)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+			reporter, err := NewReporter(output, w)
+			if err != nil {
+				return err
+			}
+
 			simulators := selectSimulators(simulatorNames)
+			for i := range simulators {
+				if o, ok := profile.Overrides[simulators[i].name]; ok && o.Interval > 0 {
+					simulators[i].interval = time.Duration(o.Interval)
+				}
+			}
 			interval := 2 * time.Second
 			if fast {
 				interval = 0
@@ -46,21 +108,42 @@ func newRunCommand() *cobra.Command {
 					simulators[i].interval = 0
 				}
 			}
-			run(simulators, extIP, interval)
+			run(simulators, extIP, interval, parallel, reporter)
 			return nil
 		},
 	}
 
 	cmd.Flags().BoolVar(&fast, "fast", false, "run simulator fast without sleep intervals")
+	cmd.Flags().IntVar(&parallel, "parallel", 1, "number of hosts to simulate concurrently per module (modules themselves always run concurrently, so total concurrency is modules * parallel)")
 	cmd.Flags().StringVarP(&ifaceName, "interface", "i", "", "network interface to use")
+	cmd.Flags().StringVar(&output, "output", "text", "output format: text, json or ndjson")
+	cmd.Flags().StringVar(&outputFile, "output-file", "", "write output to a file instead of stdout")
+	cmd.Flags().StringVar(&configPath, "config", "", "path to a flightsim config file (default $XDG_CONFIG_HOME/flightsim/flightsim.yaml)")
+	cmd.Flags().StringVar(&profileName, "profile", "", "named profile to load from the config file (only the interval override is applied per simulator; host/port/seed overrides aren't supported yet)")
 	return cmd
 }
 
+// registeredSimulatorNames returns the names of every simulator registered
+// with the simulator package, in the order `run` should offer them.
+func registeredSimulatorNames() []string {
+	var names []string
+	for _, r := range simulator.Registrations() {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
 func selectSimulators(names []string) []simulatorInfo {
 	var simulators []simulatorInfo
-	for _, s := range allsimualtors {
-		if utils.StringsContains(names, s.name) {
-			simulators = append(simulators, s)
+	for _, r := range simulator.Registrations() {
+		if utils.StringsContains(names, r.Name) {
+			simulators = append(simulators, simulatorInfo{
+				name:        r.Name,
+				infoHeaders: r.InfoHeaders,
+				infoRun:     r.InfoRun,
+				s:           r.New(),
+				interval:    r.Interval,
+			})
 		}
 	}
 	return simulators
@@ -74,94 +157,80 @@ type simulatorInfo struct {
 	interval    time.Duration
 }
 
-var allsimualtors = []simulatorInfo{
-	{
-		"c2-dns",
-		[]string{"Preparing random sample of current C2 domains"},
-		"Resolving %s",
-		simulator.NewC2DNS(),
-		500 * time.Millisecond,
-	},
-	{
-		"dga",
-		[]string{"Generating list of DGA domains"},
-		"Resolving %s",
-		simulator.NewDGA(),
-		500 * time.Millisecond,
-	},
-	{
-		"scan",
-		[]string{
-			"Preparing random sample of RFC 1918 destinations",
-			"Preparing random sample of common TCP destination ports",
-		},
-		"Port scanning %s",
-		simulator.NewPortScan(),
-		0,
-	},
-	{
-		"tunnel",
-		[]string{"Preparing DNS tunnel hostnames"},
-		"Resolving %s",
-		simulator.NewTunnel(),
-		500 * time.Millisecond,
-	},
-}
-
-func run(simulators []simulatorInfo, extIP net.IP, interval time.Duration) error {
-	printWelcome(extIP.String())
-	printHeader()
-	for _, s := range simulators {
-		printMsg(s.name, "Starting")
-		printMsg(s.name, s.infoHeaders...)
-		time.Sleep(interval)
-
-		hosts, err := s.s.Hosts()
-		if err != nil {
-			printMsg(s.name, color.RedString("failed ")+err.Error())
-		}
+func run(simulators []simulatorInfo, extIP net.IP, interval time.Duration, parallel int, reporter Reporter) error {
+	reporter.Welcome(extIP.String())
+	reporter.Header()
 
-		var prevHostname string
-		for _, host := range hosts {
-			hostname, _, err := net.SplitHostPort(host)
-			if err != nil {
-				hostname = host
-			}
+	// Modules run concurrently; each module's pool.Run call starts up to
+	// parallel workers of its own, so the bound applies per module, not
+	// across modules. syncReporter serializes their Msg/Event calls so
+	// independent modules' output lines don't interleave.
+	pool := simulator.NewPool(parallel)
+	sr := &syncReporter{r: reporter}
 
-			// only print hostname when it has changed
-			if prevHostname != hostname {
-				printMsg(s.name, fmt.Sprintf(s.infoRun, hostname))
-			}
-			s.s.Simulate(extIP, host)
-			time.Sleep(s.interval)
-			prevHostname = hostname
-		}
-		printMsg(s.name, "Finished")
+	var wg sync.WaitGroup
+	for _, s := range simulators {
+		wg.Add(1)
+		go func(s simulatorInfo) {
+			defer wg.Done()
+			runModule(s, extIP, interval, pool, sr)
+		}(s)
 	}
-	printGoodbay()
-	return nil
-}
+	wg.Wait()
 
-func printHeader() {
-	fmt.Println("Time      Module   Description")
-	fmt.Println("--------------------------------------------------------------------------------")
+	reporter.Goodbye()
+	return nil
 }
 
-func printMsg(module string, msg ...string) {
-	for i := range msg {
-		fmt.Printf("%s  %-7s  %s\n", time.Now().Format("15:04:05"), module, msg[i])
+func runModule(s simulatorInfo, extIP net.IP, interval time.Duration, pool *simulator.Pool, reporter Reporter) {
+	reporter.Msg(s.name, "Starting")
+	reporter.Msg(s.name, s.infoHeaders...)
+	time.Sleep(interval)
+
+	hosts, err := s.s.Hosts()
+	if err != nil {
+		reporter.Event(Event{
+			Timestamp:  time.Now(),
+			Module:     s.name,
+			ExternalIP: extIP.String(),
+			Status:     "error",
+			Error:      err.Error(),
+		})
 	}
-}
 
-func printWelcome(ip string) {
-	fmt.Printf(`
-AlphaSOC Network Flight Simulator™ %s (https://github.com/alphasoc/flightsim)
-The IP address of the network interface is %s
-The current time is %s
-
-`, version.Version, ip, time.Now().Format("02-Jan-06 15:04:05"))
-}
+	// pool.Run fans Simulate out across workers but still delivers
+	// results in host order, so the reported events below stay in the
+	// same order they would in a strictly serial run of this module.
+	var prevHostname string
+	pool.Run(extIP, hosts, s.s.Simulate, func(res simulator.Result) {
+		hostname, port, err := net.SplitHostPort(res.Host)
+		if err != nil {
+			hostname = res.Host
+		}
+		if u, err := url.Parse(res.Host); err == nil && u.Host != "" {
+			// Targets given as URLs (exfil-https) aren't host:port pairs.
+			hostname, port = u.Hostname(), u.Port()
+		}
 
-func printGoodbay() {
-	fmt.Printf("\nAll done! Check your SIEM for alerts using the timestamps and details above.\n")
+		// only describe the action when the hostname has changed
+		event := Event{
+			Timestamp:  time.Now(),
+			Module:     s.name,
+			ExternalIP: extIP.String(),
+			TargetHost: hostname,
+			TargetPort: port,
+			Status:     "ok",
+		}
+		if prevHostname != hostname {
+			event.Action = fmt.Sprintf(s.infoRun, hostname)
+		}
+		if res.Err != nil {
+			event.Status = "error"
+			event.Error = res.Err.Error()
+		}
+		reporter.Event(event)
+		time.Sleep(s.interval)
+		prevHostname = hostname
+	})
+	reporter.Msg(s.name, "Finished")
 }
@@ -0,0 +1,369 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alphasoc/flightsim/simulator"
+	"github.com/alphasoc/flightsim/utils"
+	"github.com/spf13/cobra"
+)
+
+func newServeCommand() *cobra.Command {
+	var (
+		addr     string
+		token    string
+		certFile string
+		keyFile  string
+		caFile   string
+	)
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run an HTTP API for triggering simulations remotely",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if token == "" && caFile == "" {
+				return fmt.Errorf("serve requires --token or --tls-client-ca for authentication")
+			}
+			if caFile != "" && (certFile == "" || keyFile == "") {
+				return fmt.Errorf("--tls-client-ca requires --tls-cert and --tls-key: mTLS can only be enforced over TLS")
+			}
+
+			srv := newAPIServer(token)
+			httpServer := &http.Server{
+				Addr:    addr,
+				Handler: srv.handler(),
+			}
+
+			if caFile != "" {
+				pool, err := loadCAPool(caFile)
+				if err != nil {
+					return err
+				}
+				httpServer.TLSConfig = &tls.Config{
+					ClientCAs:  pool,
+					ClientAuth: tls.RequireAndVerifyClientCert,
+				}
+			}
+
+			if certFile != "" && keyFile != "" {
+				return httpServer.ListenAndServeTLS(certFile, keyFile)
+			}
+			return httpServer.ListenAndServe()
+		},
+	}
+
+	cmd.Flags().StringVar(&addr, "addr", ":8080", "address to listen on")
+	cmd.Flags().StringVar(&token, "token", os.Getenv("FLIGHTSIM_TOKEN"), "bearer token required on every request")
+	cmd.Flags().StringVar(&certFile, "tls-cert", "", "TLS certificate file (enables HTTPS)")
+	cmd.Flags().StringVar(&keyFile, "tls-key", "", "TLS key file (enables HTTPS)")
+	cmd.Flags().StringVar(&caFile, "tls-client-ca", "", "CA file used to require client certificates (mTLS)")
+	return cmd
+}
+
+func loadCAPool(caFile string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+	return pool, nil
+}
+
+// runRequest is the body of POST /v1/runs.
+type runRequest struct {
+	Simulators []string `json:"simulators"`
+	Fast       bool     `json:"fast"`
+	Interface  string   `json:"interface"`
+	Parallel   int      `json:"parallel"`
+}
+
+// runStatus is the body of GET /v1/runs/{id}.
+type runStatus struct {
+	ID       string    `json:"id"`
+	Status   string    `json:"status"` // running, done, error
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// apiRun tracks one in-flight or completed run started through the API.
+type apiRun struct {
+	status runStatus
+
+	mu     sync.Mutex
+	events []Event
+	subs   map[chan Event]struct{}
+}
+
+func (r *apiRun) Welcome(ip string)                {}
+func (r *apiRun) Header()                          {}
+func (r *apiRun) Msg(module string, msg ...string) {}
+
+// Event implements Reporter by recording the event and fanning it out to
+// anyone streaming GET /v1/runs/{id}/events.
+func (r *apiRun) Event(e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+	for ch := range r.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+func (r *apiRun) Goodbye() {}
+
+func (r *apiRun) subscribe() chan Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Replay with the lock held: the channel has room for the whole
+	// backlog, so these sends cannot block, and no new event can be
+	// delivered ahead of the backlog it follows.
+	ch := make(chan Event, len(r.events)+1)
+	for _, e := range r.events {
+		ch <- e
+	}
+	r.subs[ch] = struct{}{}
+	return ch
+}
+
+func (r *apiRun) unsubscribe(ch chan Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.subs, ch)
+	close(ch)
+}
+
+// apiServer backs the `flightsim serve` HTTP API.
+type apiServer struct {
+	token string
+
+	mu   sync.Mutex
+	runs map[string]*apiRun
+}
+
+func newAPIServer(token string) *apiServer {
+	return &apiServer{token: token, runs: make(map[string]*apiRun)}
+}
+
+// handler builds the routing table by hand, dispatching on r.Method and
+// parsing the run ID out of r.URL.Path directly: the method-prefixed,
+// wildcard-segment patterns ("GET /v1/runs/{id}") that newer http.ServeMux
+// versions parse specially aren't available on the Go version this repo
+// targets, where they'd otherwise be matched as one opaque literal path.
+func (s *apiServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/simulators", s.requireMethod(http.MethodGet, s.handleSimulators))
+	mux.HandleFunc("/v1/runs", s.requireMethod(http.MethodPost, s.handleStartRun))
+	mux.HandleFunc("/v1/runs/", s.handleRunsSubPath)
+	return s.authenticate(mux)
+}
+
+// requireMethod rejects requests that don't use method before calling next.
+func (s *apiServer) requireMethod(method string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleRunsSubPath dispatches the two routes nested under /v1/runs/{id}:
+// GET /v1/runs/{id} for status, and GET /v1/runs/{id}/events for the event
+// stream.
+func (s *apiServer) handleRunsSubPath(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rest := strings.TrimPrefix(r.URL.Path, "/v1/runs/")
+	id, sub, hasSub := strings.Cut(rest, "/")
+	if id == "" || (hasSub && sub != "events") {
+		http.NotFound(w, r)
+		return
+	}
+
+	if hasSub {
+		s.handleRunEvents(w, r, id)
+	} else {
+		s.handleRunStatus(w, r, id)
+	}
+}
+
+func (s *apiServer) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.token == "" {
+			// No bearer token configured: the listener relies on the
+			// --tls-client-ca mTLS check performed during the handshake.
+			next.ServeHTTP(w, r)
+			return
+		}
+		const prefix = "Bearer "
+		header := r.Header.Get("Authorization")
+		if len(header) < len(prefix) || subtle.ConstantTimeCompare([]byte(header[len(prefix):]), []byte(s.token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *apiServer) handleSimulators(w http.ResponseWriter, r *http.Request) {
+	type simulatorDesc struct {
+		Name        string   `json:"name"`
+		InfoHeaders []string `json:"info_headers"`
+	}
+	var out []simulatorDesc
+	for _, r := range simulator.Registrations() {
+		out = append(out, simulatorDesc{Name: r.Name, InfoHeaders: r.InfoHeaders})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *apiServer) handleStartRun(w http.ResponseWriter, r *http.Request) {
+	var req runRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	names := req.Simulators
+	if len(names) == 0 {
+		names = registeredSimulatorNames()
+	}
+	for _, name := range names {
+		if !utils.StringsContains(registeredSimulatorNames(), name) {
+			http.Error(w, fmt.Sprintf("simulator %s not recognized", name), http.StatusBadRequest)
+			return
+		}
+	}
+
+	extIP, err := utils.ExternalIP(req.Interface)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := newRunID()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ar := &apiRun{
+		status: runStatus{ID: id, Status: "running", Started: time.Now()},
+		subs:   make(map[chan Event]struct{}),
+	}
+	s.mu.Lock()
+	s.runs[id] = ar
+	s.mu.Unlock()
+
+	simulators := selectSimulators(names)
+	interval := 2 * time.Second
+	if req.Fast {
+		interval = 0
+		for i := range simulators {
+			simulators[i].interval = 0
+		}
+	}
+
+	go func() {
+		err := run(simulators, extIP, interval, req.Parallel, ar)
+		ar.mu.Lock()
+		ar.status.Status = "done"
+		if err != nil {
+			ar.status.Status = "error"
+			ar.status.Error = err.Error()
+		}
+		ar.status.Finished = time.Now()
+		ar.mu.Unlock()
+	}()
+
+	writeJSON(w, http.StatusAccepted, ar.status)
+}
+
+func (s *apiServer) lookupRun(w http.ResponseWriter, id string) *apiRun {
+	s.mu.Lock()
+	ar := s.runs[id]
+	s.mu.Unlock()
+	if ar == nil {
+		http.Error(w, "run not found", http.StatusNotFound)
+		return nil
+	}
+	return ar
+}
+
+func (s *apiServer) handleRunStatus(w http.ResponseWriter, r *http.Request, id string) {
+	run := s.lookupRun(w, id)
+	if run == nil {
+		return
+	}
+	run.mu.Lock()
+	status := run.status
+	run.mu.Unlock()
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (s *apiServer) handleRunEvents(w http.ResponseWriter, r *http.Request, id string) {
+	run := s.lookupRun(w, id)
+	if run == nil {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	flusher, canFlush := w.(http.Flusher)
+
+	ch := run.subscribe()
+	defer run.unsubscribe(ch)
+
+	enc := json.NewEncoder(w)
+	for {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err := enc.Encode(e); err != nil {
+				return
+			}
+			if canFlush {
+				flusher.Flush()
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func newRunID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
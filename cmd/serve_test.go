@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alphasoc/flightsim/simulator"
+)
+
+func init() {
+	simulator.Register(simulator.Registration{
+		Name:        "test-echo",
+		InfoHeaders: []string{"test fixture"},
+		InfoRun:     "Pinging %s",
+		Interval:    0,
+		New:         func() simulator.Simulator { return &echoSimulator{} },
+	})
+}
+
+// echoSimulator is a Simulator fixture used only by this test file, so
+// serve's run lifecycle can be exercised without touching the network.
+type echoSimulator struct{}
+
+func (*echoSimulator) Hosts() ([]string, error) {
+	return []string{"127.0.0.1:0"}, nil
+}
+
+func (*echoSimulator) Simulate(net.IP, string) error {
+	return nil
+}
+
+func TestServeRequiresAuth(t *testing.T) {
+	cmd := newServeCommand()
+	if err := cmd.Flags().Set("addr", "127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.RunE(cmd, nil); err == nil {
+		t.Fatal("expected an error when neither --token nor --tls-client-ca is set")
+	}
+}
+
+func TestServeRequiresCertAndKeyWithClientCA(t *testing.T) {
+	cmd := newServeCommand()
+	if err := cmd.Flags().Set("tls-client-ca", "testdata-does-not-need-to-exist.pem"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.RunE(cmd, nil); err == nil {
+		t.Fatal("expected an error when --tls-client-ca is set without --tls-cert/--tls-key")
+	}
+}
+
+func TestAuthenticateRejectsMissingOrWrongToken(t *testing.T) {
+	srv := newAPIServer("secret")
+	ts := httptest.NewServer(srv.handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/v1/simulators")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/v1/simulators", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("wrong token: status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func TestAuthenticateAcceptsBearerToken(t *testing.T) {
+	srv := newAPIServer("secret")
+	ts := httptest.NewServer(srv.handler())
+	defer ts.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/v1/simulators", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestSimulatorsEndpoint(t *testing.T) {
+	srv := newAPIServer("")
+	ts := httptest.NewServer(srv.handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/v1/simulators")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	var out []struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, s := range out {
+		if s.Name == "test-echo" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected test-echo in %v", out)
+	}
+}
+
+func TestRunLifecycle(t *testing.T) {
+	srv := newAPIServer("")
+	ts := httptest.NewServer(srv.handler())
+	defer ts.Close()
+
+	body := strings.NewReader(`{"simulators": ["test-echo"], "fast": true}`)
+	resp, err := http.Post(ts.URL+"/v1/runs", "application/json", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusAccepted)
+	}
+
+	var started runStatus
+	if err := json.NewDecoder(resp.Body).Decode(&started); err != nil {
+		t.Fatal(err)
+	}
+	if started.ID == "" {
+		t.Fatal("expected a run ID")
+	}
+
+	var final runStatus
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		resp, err := http.Get(ts.URL + "/v1/runs/" + started.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&final)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if final.Status != "running" {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if final.Status != "done" {
+		t.Fatalf("final status = %q, want done", final.Status)
+	}
+
+	resp, err = http.Get(ts.URL + "/v1/runs/" + started.ID + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("events status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	if !scanner.Scan() {
+		t.Fatal("expected at least one buffered event on GET .../events")
+	}
+	var e Event
+	if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+		t.Fatalf("decoding event: %v", err)
+	}
+	if e.Module != "test-echo" {
+		t.Errorf("event module = %q, want test-echo", e.Module)
+	}
+}
+
+func TestRunNotFound(t *testing.T) {
+	srv := newAPIServer("")
+	ts := httptest.NewServer(srv.handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/v1/runs/does-not-exist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the top level shape of a flightsim configuration file: a set of
+// named, reusable simulation profiles.
+type Config struct {
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// Profile is one named, reusable simulation scenario: which simulators to
+// run, which interface and reporter to use, and per-simulator overrides.
+type Profile struct {
+	Simulators []string                   `yaml:"simulators"`
+	Interface  string                     `yaml:"interface"`
+	Parallel   int                        `yaml:"parallel"`
+	Output     string                     `yaml:"output"`
+	OutputFile string                     `yaml:"output_file"`
+	Overrides  map[string]SimulatorConfig `yaml:"overrides"`
+}
+
+// SimulatorConfig holds the per-simulator overrides a profile can set. Only
+// Interval is wired up today: the Simulator/Registration types have no way
+// to take target hosts, port ranges or domain feeds from a caller, so
+// sample sizes, host lists, scan port ranges, DGA seeds and C2 domain feeds
+// cannot be overridden from a profile yet, despite being part of the
+// original ask for this feature.
+type SimulatorConfig struct {
+	Interval Duration `yaml:"interval"`
+}
+
+// Duration is a time.Duration that unmarshals from a human-readable string
+// such as "250ms" or "2s", so profile authors don't have to write out raw
+// nanoseconds in YAML.
+type Duration time.Duration
+
+// UnmarshalYAML implements yaml.Unmarshaler by parsing the node's scalar
+// value with time.ParseDuration.
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	var s string
+	if err := value.Decode(&s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("parsing duration %q: %w", s, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// DefaultPath returns where flightsim looks for a config file when --config
+// isn't given: $XDG_CONFIG_HOME/flightsim/flightsim.yaml, falling back to
+// ~/.config/flightsim/flightsim.yaml.
+func DefaultPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "flightsim", "flightsim.yaml")
+}
+
+// Load reads and parses the config file at path. If path is empty, it reads
+// from DefaultPath() instead, and a missing file there is not an error: it
+// just means no profiles are available.
+func Load(path string) (*Config, error) {
+	explicit := path != ""
+	if path == "" {
+		path = DefaultPath()
+	}
+	if path == "" {
+		return &Config{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return &Config{}, nil
+		}
+		return nil, err
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Profile looks up a named profile, returning an error that's clear about
+// what's expected when the name is wrong.
+func (c *Config) Profile(name string) (Profile, error) {
+	p, ok := c.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("profile %q not defined in config", name)
+	}
+	return p, nil
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadParsesProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flightsim.yaml")
+	data := `
+profiles:
+  quick:
+    simulators: ["c2-dns", "dga"]
+    interface: eth0
+    parallel: 4
+    output: json
+    overrides:
+      scan:
+        interval: 250ms
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+
+	profile, err := cfg.Profile("quick")
+	if err != nil {
+		t.Fatalf("Profile(\"quick\") = %v", err)
+	}
+	if profile.Interface != "eth0" {
+		t.Errorf("Interface = %q, want eth0", profile.Interface)
+	}
+	if profile.Parallel != 4 {
+		t.Errorf("Parallel = %d, want 4", profile.Parallel)
+	}
+	if got := profile.Overrides["scan"].Interval; got != Duration(250*time.Millisecond) {
+		t.Errorf("scan interval = %v, want 250ms", time.Duration(got))
+	}
+}
+
+func TestLoadRejectsUnparseableDuration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flightsim.yaml")
+	data := `
+profiles:
+  quick:
+    overrides:
+      scan:
+        interval: not-a-duration
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Fatal("expected an error for an unparseable interval")
+	}
+}
+
+func TestProfileUnknownName(t *testing.T) {
+	cfg := &Config{}
+	if _, err := cfg.Profile("missing"); err == nil {
+		t.Fatal("expected an error for an undefined profile")
+	}
+}
+
+func TestLoadMissingDefaultPathIsNotAnError(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf(`Load("") with no config file present: %v`, err)
+	}
+	if len(cfg.Profiles) != 0 {
+		t.Errorf("expected no profiles, got %d", len(cfg.Profiles))
+	}
+}
+
+func TestLoadMissingExplicitPathIsAnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "does-not-exist.yaml")
+	if _, err := Load(path); err == nil {
+		t.Fatal("expected an error for a missing explicit --config path")
+	}
+}
@@ -0,0 +1,39 @@
+package simulator
+
+import "time"
+
+// init registers the four simulators flightsim has always shipped with,
+// through the same Registration path external plugin packages use.
+func init() {
+	Register(Registration{
+		Name:        "c2-dns",
+		InfoHeaders: []string{"Preparing random sample of current C2 domains"},
+		InfoRun:     "Resolving %s",
+		Interval:    500 * time.Millisecond,
+		New:         func() Simulator { return NewC2DNS() },
+	})
+	Register(Registration{
+		Name:        "dga",
+		InfoHeaders: []string{"Generating list of DGA domains"},
+		InfoRun:     "Resolving %s",
+		Interval:    500 * time.Millisecond,
+		New:         func() Simulator { return NewDGA() },
+	})
+	Register(Registration{
+		Name: "scan",
+		InfoHeaders: []string{
+			"Preparing random sample of RFC 1918 destinations",
+			"Preparing random sample of common TCP destination ports",
+		},
+		InfoRun:  "Port scanning %s",
+		Interval: 0,
+		New:      func() Simulator { return NewPortScan() },
+	})
+	Register(Registration{
+		Name:        "tunnel",
+		InfoHeaders: []string{"Preparing DNS tunnel hostnames"},
+		InfoRun:     "Resolving %s",
+		Interval:    500 * time.Millisecond,
+		New:         func() Simulator { return NewTunnel() },
+	})
+}
@@ -0,0 +1,65 @@
+package simulator
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// exfilSinkURLEnv names the environment variable that points exfil-https at
+// the HTTPS endpoint it should upload its payload to. There is no built-in
+// default: flightsim doesn't ship a hardcoded third-party URL to send
+// multi-megabyte uploads to, so operators must point this at a sink they
+// control (or are otherwise authorized to load-test).
+const exfilSinkURLEnv = "FLIGHTSIM_EXFIL_SINK_URL"
+
+// exfilPayloadMB is the size, in megabytes, of the random payload POSTed to
+// the sink.
+const exfilPayloadMB = 5
+
+// exfilHTTPS simulates a large outbound HTTPS upload, the shape of a data
+// exfiltration attempt.
+type exfilHTTPS struct{}
+
+// NewExfilHTTPS returns a Simulator that POSTs a fabricated multi-megabyte
+// payload of random data to a benign HTTPS sink, to help validate
+// volumetric/DLP detections.
+func NewExfilHTTPS() Simulator {
+	return &exfilHTTPS{}
+}
+
+func init() {
+	Register(Registration{
+		Name:        "exfil-https",
+		InfoHeaders: []string{fmt.Sprintf("Preparing %dMB payload to POST over HTTPS", exfilPayloadMB)},
+		InfoRun:     "Uploading to %s",
+		Interval:    0,
+		New:         func() Simulator { return NewExfilHTTPS() },
+	})
+}
+
+func (e *exfilHTTPS) Hosts() ([]string, error) {
+	sink := os.Getenv(exfilSinkURLEnv)
+	if sink == "" {
+		return nil, fmt.Errorf("%s is not set: point it at an HTTPS endpoint you control to receive the simulated upload", exfilSinkURLEnv)
+	}
+	return []string{sink}, nil
+}
+
+func (e *exfilHTTPS) Simulate(extIP net.IP, host string) error {
+	payload := make([]byte, exfilPayloadMB*1024*1024)
+	if _, err := rand.Read(payload); err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(host, "application/octet-stream", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
@@ -0,0 +1,66 @@
+package simulator
+
+import (
+	"math/rand"
+	"net"
+	"strconv"
+	"time"
+)
+
+// knownMiningPools are hostnames of public Stratum/JSON-RPC mining pools,
+// used to simulate a host joining a cryptomining botnet.
+var knownMiningPools = []string{
+	"pool.minexmr.com",
+	"xmr-eu1.nanopool.org",
+	"gulf.moneroocean.stream",
+	"pool.supportxmr.com",
+	"xmrpool.eu",
+}
+
+var miningPorts = []int{3333, 4444, 14444}
+
+// miner simulates a host beaconing out to known cryptomining pools over
+// their Stratum ports.
+type miner struct{}
+
+// NewMiner returns a Simulator that connects to a random sample of known
+// cryptomining pools, to help validate mining related detections.
+func NewMiner() Simulator {
+	return &miner{}
+}
+
+func init() {
+	Register(Registration{
+		Name:        "miner",
+		InfoHeaders: []string{"Preparing random sample of known cryptomining pools"},
+		InfoRun:     "Connecting to %s",
+		Interval:    500 * time.Millisecond,
+		New:         func() Simulator { return NewMiner() },
+	})
+}
+
+func (m *miner) Hosts() ([]string, error) {
+	pools := make([]string, len(knownMiningPools))
+	copy(pools, knownMiningPools)
+	rand.Shuffle(len(pools), func(i, j int) { pools[i], pools[j] = pools[j], pools[i] })
+
+	n := 3
+	if n > len(pools) {
+		n = len(pools)
+	}
+
+	hosts := make([]string, 0, n)
+	for _, pool := range pools[:n] {
+		port := miningPorts[rand.Intn(len(miningPorts))]
+		hosts = append(hosts, net.JoinHostPort(pool, strconv.Itoa(port)))
+	}
+	return hosts, nil
+}
+
+func (m *miner) Simulate(extIP net.IP, host string) error {
+	conn, err := net.DialTimeout("tcp", host, 3*time.Second)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
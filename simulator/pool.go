@@ -0,0 +1,93 @@
+package simulator
+
+import (
+	"net"
+	"sync"
+)
+
+// Result is the outcome of simulating a single host.
+type Result struct {
+	Host string
+	Err  error
+}
+
+// Pool runs Simulate calls for a batch of hosts across a bounded number of
+// worker goroutines. Results are delivered to the caller in the same order
+// the hosts were submitted, regardless of which worker finishes first, so
+// that callers printing one line per host don't see interleaved output.
+type Pool struct {
+	workers int
+}
+
+// NewPool returns a Pool whose Run calls each use up to n concurrent
+// workers. n < 1 is treated as 1, i.e. hosts are simulated one at a time.
+func NewPool(n int) *Pool {
+	if n < 1 {
+		n = 1
+	}
+	return &Pool{workers: n}
+}
+
+// Run dispatches fn(extIP, host) for every host across the pool's workers
+// and invokes onResult for each one, in host order.
+func (p *Pool) Run(extIP net.IP, hosts []string, fn func(net.IP, string) error, onResult func(Result)) {
+	if len(hosts) == 0 {
+		return
+	}
+
+	type job struct {
+		index int
+		host  string
+	}
+
+	jobs := make(chan job)
+	results := make(chan struct {
+		index int
+		res   Result
+	}, len(hosts))
+
+	var wg sync.WaitGroup
+	workers := p.workers
+	if workers > len(hosts) {
+		workers = len(hosts)
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				results <- struct {
+					index int
+					res   Result
+				}{j.index, Result{Host: j.host, Err: fn(extIP, j.host)}}
+			}
+		}()
+	}
+
+	go func() {
+		for i, h := range hosts {
+			jobs <- job{i, h}
+		}
+		close(jobs)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	pending := make(map[int]Result, len(hosts))
+	next := 0
+	for r := range results {
+		pending[r.index] = r.res
+		for {
+			res, ok := pending[next]
+			if !ok {
+				break
+			}
+			onResult(res)
+			delete(pending, next)
+			next++
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package simulator
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPoolRunPreservesHostOrder(t *testing.T) {
+	hosts := []string{"a", "b", "c", "d", "e"}
+
+	// Finish in reverse order of submission to prove onResult still
+	// delivers results in host order regardless of which worker finishes
+	// its job first.
+	delays := map[string]time.Duration{
+		"a": 50 * time.Millisecond,
+		"b": 40 * time.Millisecond,
+		"c": 30 * time.Millisecond,
+		"d": 20 * time.Millisecond,
+		"e": 10 * time.Millisecond,
+	}
+
+	pool := NewPool(len(hosts))
+
+	var mu sync.Mutex
+	var got []string
+	pool.Run(net.ParseIP("127.0.0.1"), hosts, func(_ net.IP, host string) error {
+		time.Sleep(delays[host])
+		return nil
+	}, func(res Result) {
+		mu.Lock()
+		got = append(got, res.Host)
+		mu.Unlock()
+	})
+
+	if len(got) != len(hosts) {
+		t.Fatalf("got %d results, want %d", len(got), len(hosts))
+	}
+	for i, host := range hosts {
+		if got[i] != host {
+			t.Errorf("result %d = %s, want %s", i, got[i], host)
+		}
+	}
+}
+
+func TestPoolRunReportsErrors(t *testing.T) {
+	errBadHost := errors.New("bad host")
+	pool := NewPool(2)
+
+	var results []Result
+	pool.Run(net.ParseIP("127.0.0.1"), []string{"ok", "bad"}, func(_ net.IP, host string) error {
+		if host == "bad" {
+			return errBadHost
+		}
+		return nil
+	}, func(res Result) {
+		results = append(results, res)
+	})
+
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if results[0].Err != nil {
+		t.Errorf("results[0].Err = %v, want nil", results[0].Err)
+	}
+	if results[1].Err != errBadHost {
+		t.Errorf("results[1].Err = %v, want %v", results[1].Err, errBadHost)
+	}
+}
+
+func TestPoolRunEmptyHosts(t *testing.T) {
+	pool := NewPool(4)
+	called := false
+	pool.Run(net.ParseIP("127.0.0.1"), nil, func(net.IP, string) error {
+		return nil
+	}, func(Result) {
+		called = true
+	})
+	if called {
+		t.Error("onResult called for an empty host list")
+	}
+}
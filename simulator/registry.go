@@ -0,0 +1,48 @@
+package simulator
+
+import (
+	"sort"
+	"time"
+)
+
+// Factory constructs a new instance of a registered Simulator.
+type Factory func() Simulator
+
+// Registration describes a simulator module as `flightsim run` should treat
+// it: its name, the progress lines printed before it starts, the template
+// used to announce each target, and the default pacing between targets.
+type Registration struct {
+	Name        string
+	InfoHeaders []string
+	InfoRun     string
+	Interval    time.Duration
+	New         Factory
+}
+
+var registry = make(map[string]Registration)
+
+// Register makes a simulator available under r.Name. It's meant to be
+// called from the init() of the package implementing the simulator, so
+// that new modules - including ones in external plugin packages - become
+// available just by being imported.
+func Register(r Registration) {
+	if _, exists := registry[r.Name]; exists {
+		panic("simulator: Register called twice for " + r.Name)
+	}
+	registry[r.Name] = r
+}
+
+// Registrations returns every registered simulator, sorted by name.
+func Registrations() []Registration {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := make([]Registration, 0, len(names))
+	for _, name := range names {
+		out = append(out, registry[name])
+	}
+	return out
+}
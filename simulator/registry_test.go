@@ -0,0 +1,24 @@
+package simulator
+
+import "testing"
+
+func TestRegistrationsSortedByName(t *testing.T) {
+	regs := Registrations()
+	if len(regs) == 0 {
+		t.Fatal("Registrations() returned none; expected at least the built-in simulators")
+	}
+	for i := 1; i < len(regs); i++ {
+		if regs[i-1].Name > regs[i].Name {
+			t.Fatalf("Registrations() not sorted: %q before %q", regs[i-1].Name, regs[i].Name)
+		}
+	}
+}
+
+func TestRegisterPanicsOnDuplicateName(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Register did not panic on a duplicate name")
+		}
+	}()
+	Register(Registration{Name: "scan", New: func() Simulator { return nil }})
+}
@@ -0,0 +1,54 @@
+package simulator
+
+import (
+	"crypto/tls"
+	"net"
+	"time"
+)
+
+// torDirectoryAuthorities are the well-known Tor directory authorities, used
+// to simulate a host bootstrapping into the Tor network.
+var torDirectoryAuthorities = []string{
+	"128.31.0.39:443",    // moria1
+	"86.59.21.38:443",    // tor26
+	"66.111.2.131:443",   // dizum
+	"131.188.40.189:443", // Serge
+	"193.23.244.244:443", // dannenberg
+}
+
+// tor simulates a host establishing TLS connections to known Tor directory
+// authorities.
+type tor struct{}
+
+// NewTor returns a Simulator that performs TLS handshakes against known Tor
+// directory authorities, to help validate Tor related detections.
+func NewTor() Simulator {
+	return &tor{}
+}
+
+func init() {
+	Register(Registration{
+		Name:        "tor",
+		InfoHeaders: []string{"Preparing list of Tor directory authorities"},
+		InfoRun:     "Connecting to %s",
+		Interval:    500 * time.Millisecond,
+		New:         func() Simulator { return NewTor() },
+	})
+}
+
+func (t *tor) Hosts() ([]string, error) {
+	hosts := make([]string, len(torDirectoryAuthorities))
+	copy(hosts, torDirectoryAuthorities)
+	return hosts, nil
+}
+
+func (t *tor) Simulate(extIP net.IP, host string) error {
+	// Tor relays don't present certificates that verify against a public
+	// root, so the handshake itself is what's being simulated, not trust.
+	dialer := &net.Dialer{Timeout: 3 * time.Second}
+	conn, err := tls.DialWithDialer(dialer, "tcp", host, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}